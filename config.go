@@ -0,0 +1,211 @@
+// config.go
+// Declarative process list loaded from a -config YAML or TOML file, merged
+// alongside whatever -i/-c flags were also given.
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// HealthcheckFileConfig is the on-disk form of a process's healthcheck;
+// Interval/Timeout/StartPeriod are plain duration strings (e.g. "5s") since
+// neither the yaml nor toml decoder parses time.Duration natively.
+type HealthcheckFileConfig struct {
+	Mode        string `yaml:"mode" toml:"mode"` // "exec" (default), "http" or "tcp"
+	Command     string `yaml:"command" toml:"command"`
+	URL         string `yaml:"url" toml:"url"`
+	Address     string `yaml:"address" toml:"address"`
+	Interval    string `yaml:"interval" toml:"interval"`
+	Timeout     string `yaml:"timeout" toml:"timeout"`
+	StartPeriod string `yaml:"start_period" toml:"start_period"`
+	Retries     int    `yaml:"retries" toml:"retries"`
+}
+
+// ProcessFileConfig is the on-disk form of a single managed process.
+type ProcessFileConfig struct {
+	Name           string                 `yaml:"name" toml:"name"`
+	Command        string                 `yaml:"command" toml:"command"`
+	Type           string                 `yaml:"type" toml:"type"` // "init" or "service", default "service"
+	Env            map[string]string      `yaml:"env" toml:"env"`
+	Cwd            string                 `yaml:"cwd" toml:"cwd"`
+	User           string                 `yaml:"user" toml:"user"`
+	DependsOn      []string               `yaml:"depends_on" toml:"depends_on"`
+	Restart        string                 `yaml:"restart" toml:"restart"`
+	Healthcheck    *HealthcheckFileConfig `yaml:"healthcheck" toml:"healthcheck"`
+	ForwardSignals bool                   `yaml:"forward_signals" toml:"forward_signals"`
+}
+
+// FileConfig is the top-level shape of a -config file.
+type FileConfig struct {
+	Processes []ProcessFileConfig `yaml:"processes" toml:"processes"`
+}
+
+// LoadConfigFile reads a process list from path, picking YAML or TOML based
+// on its extension.
+func LoadConfigFile(path string) (*FileConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config %s: %w", path, err)
+	}
+
+	var cfg FileConfig
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("parse yaml config %s: %w", path, err)
+		}
+	case ".toml":
+		if _, err := toml.Decode(string(data), &cfg); err != nil {
+			return nil, fmt.Errorf("parse toml config %s: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported config extension %q (want .yaml, .yml or .toml)", ext)
+	}
+	return &cfg, nil
+}
+
+// LoadConfig reads path and adds every process it describes via AddProcess;
+// main adds any -i/-c flag processes to the same list after it.
+func (m *Manager) LoadConfig(path string) error {
+	cfg, err := LoadConfigFile(path)
+	if err != nil {
+		return err
+	}
+	for _, pc := range cfg.Processes {
+		if _, err := m.addConfiguredProcess(path, pc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReloadConfig re-reads path and adds any process it describes that isn't
+// already managed, for the control socket's "reload" command. Processes
+// already running are left exactly as they are; god does not support
+// redefining a running process's command, policy or healthcheck in place.
+// The merged graph (existing processes plus the newly added ones) is
+// cycle-checked before anything new is started, and each new process is
+// started through the same dependency-aware path Start() uses, so
+// depends_on is honored for reload-added processes exactly like it is at
+// startup.
+func (m *Manager) ReloadConfig(path string) error {
+	cfg, err := LoadConfigFile(path)
+	if err != nil {
+		return err
+	}
+
+	var added []*Process
+	for _, pc := range cfg.Processes {
+		if m.findProcess(pc.Name) != nil {
+			continue
+		}
+		proc, err := m.addConfiguredProcess(path, pc)
+		if err != nil {
+			return err
+		}
+		added = append(added, proc)
+	}
+
+	deps, err := m.buildDependencyIndex()
+	if err != nil {
+		return err
+	}
+	if cycle := detectCycle(deps); cycle != nil {
+		return fmt.Errorf("dependency cycle detected: %s", strings.Join(cycle, " -> "))
+	}
+
+	for _, proc := range added {
+		if proc.Healthcheck != nil && proc.Healthcheck.configured() {
+			go m.runHealthcheckMonitor(proc)
+		}
+		switch proc.Type {
+		case TaskTypeService:
+			if err := m.StartProcess(proc.Name); err != nil {
+				log.Printf("Reload %s: failed to start %q: %v", path, proc.Name, err)
+			}
+		case TaskTypeInit:
+			go m.runInitTaskDependencyAware(proc)
+		}
+	}
+	log.Printf("Reload %s: added %d new process(es)", path, len(added))
+	return nil
+}
+
+// addConfiguredProcess validates pc, registers it with m via AddProcess and
+// returns the new Process.
+func (m *Manager) addConfiguredProcess(path string, pc ProcessFileConfig) (*Process, error) {
+	if pc.Name == "" {
+		return nil, fmt.Errorf("config %s: process with empty name", path)
+	}
+	if pc.Command == "" {
+		return nil, fmt.Errorf("config %s: process %q has no command", path, pc.Name)
+	}
+
+	taskType := TaskTypeService
+	switch pc.Type {
+	case "", "service":
+		taskType = TaskTypeService
+	case "init":
+		taskType = TaskTypeInit
+	default:
+		return nil, fmt.Errorf("config %s: process %q has unknown type %q", path, pc.Name, pc.Type)
+	}
+
+	proc := m.AddProcess(pc.Name, pc.Command, taskType)
+	proc.Cwd = pc.Cwd
+	proc.User = pc.User
+	proc.DependsOn = pc.DependsOn
+	proc.ForwardSignals = pc.ForwardSignals
+
+	for k, v := range pc.Env {
+		proc.Env = append(proc.Env, k+"="+v)
+	}
+
+	if taskType == TaskTypeService {
+		policy, err := ParseRestartPolicy(pc.Restart)
+		if err != nil {
+			return nil, fmt.Errorf("config %s: process %q: %w", path, pc.Name, err)
+		}
+		proc.Policy = policy
+	}
+
+	if pc.Healthcheck != nil {
+		mode, err := ParseHealthcheckMode(pc.Healthcheck.Mode)
+		if err != nil {
+			return nil, fmt.Errorf("config %s: process %q: %w", path, pc.Name, err)
+		}
+		hc := &HealthcheckSpec{
+			Mode:    mode,
+			Command: pc.Healthcheck.Command,
+			URL:     pc.Healthcheck.URL,
+			Address: pc.Healthcheck.Address,
+			Retries: pc.Healthcheck.Retries,
+		}
+		if hc.Interval, err = parseOptionalDuration(pc.Healthcheck.Interval); err != nil {
+			return nil, fmt.Errorf("config %s: process %q: healthcheck interval: %w", path, pc.Name, err)
+		}
+		if hc.Timeout, err = parseOptionalDuration(pc.Healthcheck.Timeout); err != nil {
+			return nil, fmt.Errorf("config %s: process %q: healthcheck timeout: %w", path, pc.Name, err)
+		}
+		if hc.StartPeriod, err = parseOptionalDuration(pc.Healthcheck.StartPeriod); err != nil {
+			return nil, fmt.Errorf("config %s: process %q: healthcheck start_period: %w", path, pc.Name, err)
+		}
+		proc.Healthcheck = hc
+	}
+	return proc, nil
+}
+
+func parseOptionalDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(s)
+}
@@ -0,0 +1,234 @@
+// control.go
+// Unix domain control socket for runtime process control. The same binary,
+// invoked as "god ctl <command> [args...]", connects to the socket instead
+// of starting the supervisor, writes one newline-delimited command, prints
+// the response and exits.
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+const defaultControlSocket = "/var/run/god.sock"
+
+// ServeControlSocket listens on path (replacing any stale socket file left
+// behind by a previous run) and serves HandleControl on every connection
+// until Manager.ctx is cancelled.
+func (m *Manager) ServeControlSocket(path string) error {
+	_ = os.Remove(path)
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return fmt.Errorf("listen on control socket %s: %w", path, err)
+	}
+
+	go func() {
+		<-m.ctx.Done()
+		ln.Close()
+		_ = os.Remove(path)
+	}()
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return // listener closed, e.g. during Shutdown
+			}
+			go m.HandleControl(conn)
+		}
+	}()
+
+	log.Printf("Control socket listening on %s", path)
+	return nil
+}
+
+// HandleControl reads newline-delimited commands from conn, dispatches each
+// to StopProcess/StartProcess/RestartProcess/etc, and writes one
+// newline-delimited response per command until the client disconnects.
+func (m *Manager) HandleControl(conn net.Conn) {
+	defer conn.Close()
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		resp := m.dispatchControl(line)
+		if _, err := io.WriteString(conn, resp+"\n"); err != nil {
+			return
+		}
+	}
+}
+
+// dispatchControl runs one control command and returns its response text.
+// Responses start with "OK" or "ERR <message>", except "status" and "tail"
+// which return their result directly.
+func (m *Manager) dispatchControl(line string) string {
+	fields := strings.Fields(line)
+	cmd := fields[0]
+	args := fields[1:]
+
+	switch cmd {
+	case "status":
+		return m.controlStatus()
+	case "start":
+		if len(args) != 1 {
+			return "ERR usage: start <name>"
+		}
+		if err := m.StartProcess(args[0]); err != nil {
+			return "ERR " + err.Error()
+		}
+		return "OK"
+	case "stop":
+		if len(args) != 1 {
+			return "ERR usage: stop <name>"
+		}
+		if err := m.StopProcess(args[0]); err != nil {
+			return "ERR " + err.Error()
+		}
+		return "OK"
+	case "restart":
+		if len(args) != 1 {
+			return "ERR usage: restart <name>"
+		}
+		if err := m.RestartProcess(args[0]); err != nil {
+			return "ERR " + err.Error()
+		}
+		return "OK"
+	case "reload":
+		if m.ConfigPath == "" {
+			return "ERR no -config file loaded, nothing to reload"
+		}
+		if err := m.ReloadConfig(m.ConfigPath); err != nil {
+			return "ERR " + err.Error()
+		}
+		return "OK"
+	case "tail":
+		if len(args) < 1 || len(args) > 2 {
+			return "ERR usage: tail <name> [n]"
+		}
+		proc := m.findProcess(args[0])
+		if proc == nil {
+			return fmt.Sprintf("ERR no such process %q", args[0])
+		}
+		n := 50
+		if len(args) == 2 {
+			v, err := strconv.Atoi(args[1])
+			if err != nil {
+				return "ERR invalid line count: " + args[1]
+			}
+			n = v
+		}
+		return strings.TrimRight(string(proc.Logs.Tail(n)), "\n")
+	case "signal":
+		if len(args) != 2 {
+			return "ERR usage: signal <name> <SIG>"
+		}
+		return m.controlSignal(args[0], args[1])
+	default:
+		return fmt.Sprintf("ERR unknown command %q", cmd)
+	}
+}
+
+// controlStatus renders one line per managed process for "god ctl status".
+func (m *Manager) controlStatus() string {
+	var b strings.Builder
+	for _, proc := range m.snapshotProcesses() {
+		proc.mu.Lock()
+		fmt.Fprintf(&b, "%s: type=%s alive=%t state=%s restarts=%d exitcode=%d\n",
+			proc.Name, proc.Type, proc.Alive, proc.State, proc.RestartCount, proc.ExitCode)
+		proc.mu.Unlock()
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// controlSignal delivers sig to name's process group for the "signal"
+// control command, independent of ForwardSignal's opt-in broadcast.
+func (m *Manager) controlSignal(name, sigName string) string {
+	proc := m.findProcess(name)
+	if proc == nil {
+		return fmt.Sprintf("ERR no such process %q", name)
+	}
+	sig, err := parseSignalName(sigName)
+	if err != nil {
+		return "ERR " + err.Error()
+	}
+	proc.mu.Lock()
+	pgid := proc.Pgid
+	proc.mu.Unlock()
+	if pgid == 0 {
+		return fmt.Sprintf("ERR process %q is not running", name)
+	}
+	if err := syscall.Kill(-pgid, sig); err != nil {
+		return "ERR " + err.Error()
+	}
+	return "OK"
+}
+
+// parseSignalName maps a signal name like "TERM" or "SIGTERM" to a
+// syscall.Signal for the "signal" control command.
+func parseSignalName(name string) (syscall.Signal, error) {
+	name = strings.ToUpper(strings.TrimPrefix(strings.ToUpper(name), "SIG"))
+	switch name {
+	case "HUP":
+		return syscall.SIGHUP, nil
+	case "INT":
+		return syscall.SIGINT, nil
+	case "QUIT":
+		return syscall.SIGQUIT, nil
+	case "TERM":
+		return syscall.SIGTERM, nil
+	case "KILL":
+		return syscall.SIGKILL, nil
+	case "USR1":
+		return syscall.SIGUSR1, nil
+	case "USR2":
+		return syscall.SIGUSR2, nil
+	case "WINCH":
+		return syscall.SIGWINCH, nil
+	default:
+		return 0, fmt.Errorf("unknown signal %q", name)
+	}
+}
+
+// RunControlClient implements "god ctl [-ctl path] <command> [args...]": it
+// connects to the control socket, sends one command and prints the
+// response, for use from scripts or interactively instead of the long-lived
+// supervisor.
+func RunControlClient(args []string) {
+	sockPath := defaultControlSocket
+	if len(args) >= 2 && args[0] == "-ctl" {
+		sockPath = args[1]
+		args = args[2:]
+	}
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: god ctl [-ctl path] <status|start|stop|restart|reload|tail|signal> [args...]")
+		os.Exit(2)
+	}
+
+	conn, err := net.Dial("unix", sockPath)
+	if err != nil {
+		log.Fatalf("connect to control socket %s: %v", sockPath, err)
+	}
+	defer conn.Close()
+
+	if _, err := io.WriteString(conn, strings.Join(args, " ")+"\n"); err != nil {
+		log.Fatalf("write command: %v", err)
+	}
+	// Half-close so HandleControl sees EOF after this one command and closes
+	// the connection, ending the read loop below.
+	if uc, ok := conn.(*net.UnixConn); ok {
+		_ = uc.CloseWrite()
+	}
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		fmt.Println(scanner.Text())
+	}
+}
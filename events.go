@@ -0,0 +1,103 @@
+// events.go
+// Structured event stream: Manager publishes an Event for every notable
+// state change, fanned out to GET /events subscribers as newline-delimited
+// JSON so external supervisors can react without polling /health.
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// EventType enumerates the kinds of state change Manager publishes.
+type EventType string
+
+const (
+	EventStarted       EventType = "started"
+	EventExited        EventType = "exited"
+	EventRestarted     EventType = "restarted"
+	EventHealthChanged EventType = "health_changed"
+	EventInitDone      EventType = "init_done"
+)
+
+// Event describes a single state change in a managed process, or (for
+// EventInitDone, where Process is empty) in the manager as a whole.
+type Event struct {
+	Timestamp time.Time `json:"timestamp"`
+	Process   string    `json:"process,omitempty"`
+	Type      EventType `json:"type"`
+	ExitCode  int       `json:"exit_code,omitempty"`
+	Message   string    `json:"message,omitempty"`
+}
+
+// emit publishes ev to every live /events subscriber, dropping any that are
+// too slow to keep up rather than blocking the caller.
+func (m *Manager) emit(ev Event) {
+	m.eventsMu.Lock()
+	defer m.eventsMu.Unlock()
+	alive := m.eventSubs[:0]
+	for _, ch := range m.eventSubs {
+		select {
+		case ch <- ev:
+			alive = append(alive, ch)
+		default:
+			close(ch)
+		}
+	}
+	m.eventSubs = alive
+}
+
+// subscribeEvents registers a new live listener for future events. The
+// returned cancel func must be called to unsubscribe and release the channel.
+func (m *Manager) subscribeEvents() (ch chan Event, cancel func()) {
+	ch = make(chan Event, 32)
+
+	m.eventsMu.Lock()
+	m.eventSubs = append(m.eventSubs, ch)
+	m.eventsMu.Unlock()
+
+	cancel = func() {
+		m.eventsMu.Lock()
+		defer m.eventsMu.Unlock()
+		for i, s := range m.eventSubs {
+			if s == ch {
+				m.eventSubs = append(m.eventSubs[:i], m.eventSubs[i+1:]...)
+				close(ch)
+				return
+			}
+		}
+	}
+	return ch, cancel
+}
+
+// EventsHandler serves GET /events, streaming every future Event as one line
+// of JSON each until the client disconnects.
+func (m *Manager) EventsHandler(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	ch, cancel := m.subscribeEvents()
+	defer cancel()
+
+	enc := json.NewEncoder(w)
+	for {
+		select {
+		case ev, ok := <-ch:
+			if !ok {
+				return
+			}
+			if err := enc.Encode(ev); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,212 @@
+// healthcheck.go
+// Active per-service healthchecks (exec/http/tcp), modeled after Docker's
+// HEALTHCHECK: a service can be Alive yet still Unhealthy, and enough
+// consecutive failures triggers a restart via the normal restart policy.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// configured reports whether hc has a probe to actually run.
+func (hc *HealthcheckSpec) configured() bool {
+	switch hc.Mode {
+	case HealthcheckHTTP:
+		return hc.URL != ""
+	case HealthcheckTCP:
+		return hc.Address != ""
+	default:
+		return hc.Command != ""
+	}
+}
+
+// runHealthcheck runs hc's probe once, succeeding within hc.Timeout.
+func runHealthcheck(name string, hc *HealthcheckSpec) bool {
+	timeout := hc.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	var err error
+	switch hc.Mode {
+	case HealthcheckHTTP:
+		err = probeHTTP(hc.URL, timeout)
+	case HealthcheckTCP:
+		err = probeTCP(hc.Address, timeout)
+	default:
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		err = exec.CommandContext(ctx, "sh", "-c", hc.Command).Run()
+	}
+	if err != nil {
+		log.Printf("[%s] Healthcheck (%s) failed: %v", name, hc.Mode, err)
+		return false
+	}
+	return true
+}
+
+// probeHTTP GETs url, treating any 2xx status as healthy.
+func probeHTTP(url string, timeout time.Duration) error {
+	client := http.Client{Timeout: timeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// probeTCP succeeds if address can be dialed within timeout.
+func probeTCP(address string, timeout time.Duration) error {
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// runHealthcheckMonitor runs proc's ongoing healthcheck for as long as proc
+// is managed, across every (re)start, until Manager.ctx is cancelled. Each
+// new run gets a fresh StartPeriod grace window: failures during it are
+// reported but don't count towards Retries. Once Retries consecutive
+// failures have been seen outside that window, proc is marked Unhealthy and
+// restarted; runServiceTask's normal restart policy takes it from there.
+func (m *Manager) runHealthcheckMonitor(proc *Process) {
+	hc := proc.Healthcheck
+	interval := hc.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	retries := hc.Retries
+	if retries <= 0 {
+		retries = 1
+	}
+
+	var runPgid int
+	var startedAt time.Time
+
+	for {
+		select {
+		case <-time.After(interval):
+		case <-m.ctx.Done():
+			return
+		}
+
+		proc.mu.Lock()
+		alive := proc.Alive
+		pgid := proc.Pgid
+		proc.mu.Unlock()
+		if !alive {
+			runPgid = 0
+			continue
+		}
+		if pgid != runPgid {
+			runPgid = pgid
+			startedAt = time.Now()
+			proc.mu.Lock()
+			proc.Health = HealthStarting
+			proc.HealthFailures = 0
+			proc.mu.Unlock()
+		}
+
+		healthy := runHealthcheck(proc.Name, hc)
+		if !healthy {
+			healthcheckFailuresTotal.WithLabelValues(proc.Name).Inc()
+		}
+		inStartPeriod := time.Since(startedAt) < hc.StartPeriod
+
+		proc.mu.Lock()
+		before := proc.Health
+		switch {
+		case healthy:
+			proc.Health = HealthHealthy
+			proc.HealthFailures = 0
+		case inStartPeriod:
+			// Failing before StartPeriod elapses doesn't count against Retries.
+		default:
+			proc.HealthFailures++
+			if proc.HealthFailures >= retries {
+				proc.Health = HealthUnhealthy
+			}
+		}
+		after := proc.Health
+		proc.mu.Unlock()
+
+		if after != before {
+			m.emit(Event{Timestamp: time.Now(), Process: proc.Name, Type: EventHealthChanged, Message: after.String()})
+		}
+		if after == HealthUnhealthy {
+			log.Printf("[%s] Unhealthy after %d consecutive failed healthchecks, restarting", proc.Name, retries)
+			m.restartUnhealthy(proc, pgid)
+		}
+	}
+}
+
+// restartUnhealthy asks proc's own process group to stop; its runServiceTask
+// loop sees the exit and applies the normal restart policy from there.
+func (m *Manager) restartUnhealthy(proc *Process, pgid int) {
+	if pgid == 0 {
+		return
+	}
+	if err := syscall.Kill(-pgid, syscall.SIGTERM); err != nil && err != syscall.ESRCH {
+		log.Printf("[%s] Failed to signal unhealthy process group %d: %v", proc.Name, pgid, err)
+	}
+}
+
+// healthResponse is the JSON shape returned by HealthHandler.
+type healthResponse struct {
+	Name           string `json:"name"`
+	Alive          bool   `json:"alive"`
+	State          string `json:"state"`
+	Health         string `json:"health,omitempty"`
+	HealthFailures int    `json:"health_failures,omitempty"`
+	RestartCount   int    `json:"restart_count"`
+	Pgid           int    `json:"pgid"`
+}
+
+// HealthHandler serves GET /health/{name} with a single process's status as
+// JSON, including its active-healthcheck state if one is configured.
+func (m *Manager) HealthHandler(w http.ResponseWriter, r *http.Request) {
+	name := strings.Trim(strings.TrimPrefix(r.URL.Path, "/health/"), "/")
+	if name == "" {
+		http.NotFound(w, r)
+		return
+	}
+	proc := m.findProcess(name)
+	if proc == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	proc.mu.Lock()
+	resp := healthResponse{
+		Name:         proc.Name,
+		Alive:        proc.Alive,
+		State:        proc.State.String(),
+		RestartCount: proc.RestartCount,
+		Pgid:         proc.Pgid,
+	}
+	if proc.Healthcheck != nil && proc.Healthcheck.configured() {
+		resp.Health = proc.Health.String()
+		resp.HealthFailures = proc.HealthFailures
+	}
+	proc.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if resp.Health == HealthUnhealthy.String() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(resp)
+}
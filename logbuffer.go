@@ -0,0 +1,97 @@
+// logbuffer.go
+// Per-process ring buffer that retains recent combined stdout/stderr output
+// and fans it out to live subscribers (used by the /logs HTTP endpoints).
+package main
+
+import (
+	"bytes"
+	"sync"
+)
+
+// ProcessLogBuffer is an io.Writer that keeps the last maxBytes of output
+// for a process and broadcasts every write to any subscribed channel. It is
+// meant to be combined with the existing prefixedWriter via io.MultiWriter
+// so output still reaches the parent's stdout/stderr as before.
+type ProcessLogBuffer struct {
+	mu          sync.Mutex
+	maxBytes    int
+	data        []byte
+	subscribers []chan []byte
+}
+
+// NewProcessLogBuffer creates a ring buffer capped at maxBytes of retained
+// output. maxBytes <= 0 means unbounded.
+func NewProcessLogBuffer(maxBytes int) *ProcessLogBuffer {
+	return &ProcessLogBuffer{maxBytes: maxBytes}
+}
+
+// Write implements io.Writer, appending to the ring buffer and broadcasting
+// the chunk to subscribers. It never fails.
+func (b *ProcessLogBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	b.data = append(b.data, p...)
+	if b.maxBytes > 0 {
+		if over := len(b.data) - b.maxBytes; over > 0 {
+			b.data = b.data[over:]
+		}
+	}
+
+	chunk := append([]byte(nil), p...)
+	alive := b.subscribers[:0]
+	for _, ch := range b.subscribers {
+		select {
+		case ch <- chunk:
+			alive = append(alive, ch)
+		default:
+			// Subscriber is too slow to keep up; drop it rather than block the process.
+			close(ch)
+		}
+	}
+	b.subscribers = alive
+	b.mu.Unlock()
+
+	return len(p), nil
+}
+
+// Tail returns the last n lines of buffered output, or everything buffered
+// if n <= 0.
+func (b *ProcessLogBuffer) Tail(n int) []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if n <= 0 {
+		return append([]byte(nil), b.data...)
+	}
+	lines := bytes.Split(bytes.TrimRight(b.data, "\n"), []byte("\n"))
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	out := bytes.Join(lines, []byte("\n"))
+	if len(out) > 0 {
+		out = append(out, '\n')
+	}
+	return out
+}
+
+// Subscribe registers a new live listener for future writes. The returned
+// cancel func must be called to unsubscribe and release the channel.
+func (b *ProcessLogBuffer) Subscribe() (ch chan []byte, cancel func()) {
+	ch = make(chan []byte, 16)
+
+	b.mu.Lock()
+	b.subscribers = append(b.subscribers, ch)
+	b.mu.Unlock()
+
+	cancel = func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for i, s := range b.subscribers {
+			if s == ch {
+				b.subscribers = append(b.subscribers[:i], b.subscribers[i+1:]...)
+				close(ch)
+				return
+			}
+		}
+	}
+	return ch, cancel
+}
@@ -6,12 +6,29 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
 	"os/exec"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	defaultBackoffInitial = time.Second
+	defaultBackoffMax     = 30 * time.Second
+
+	defaultLogBufferBytes = 64 * 1024
+
+	defaultShutdownGrace = 10 * time.Second
 )
 
 type TaskType int
@@ -21,102 +38,437 @@ const (
 	TaskTypeService                 // Long-running service
 )
 
+func (t TaskType) String() string {
+	if t == TaskTypeInit {
+		return "init"
+	}
+	return "service"
+}
+
 type Manager struct {
-	processes []*Process
-	wg        sync.WaitGroup
-	ctx       context.Context
-	cancel    context.CancelFunc
-	initDone  chan struct{} // Signal when all init tasks are done
-	debug     bool          // Enable debug logging
+	processesMu sync.RWMutex // Guards processes itself (append/lookup/iterate), not Process fields
+	processes   []*Process
+	wg          sync.WaitGroup
+	ctx         context.Context
+	cancel      context.CancelFunc
+	initDone    chan struct{} // Signal when all init tasks are done
+	debug       bool          // Enable debug logging
+
+	LogBufferBytes int           // Per-process ring buffer size for captured logs
+	ShutdownGrace  time.Duration // How long to wait after SIGTERM before SIGKILL
+	ConfigPath     string        // Path last passed to LoadConfig, used by the control socket's "reload"
+
+	pidsMu        sync.Mutex
+	trackedPIDs   map[int]*Process // Children we launched, keyed by pid; used to tell reaped zombies apart from real orphans
+	zombiesReaped int64            // Orphaned children reaped while running as PID 1, accessed via sync/atomic
+
+	eventsMu  sync.Mutex
+	eventSubs []chan Event // Live /events subscribers
 }
 
 func NewManager(debug bool) *Manager {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &Manager{
-		ctx:      ctx,
-		cancel:   cancel,
-		initDone: make(chan struct{}),
-		debug:    debug,
+		ctx:            ctx,
+		cancel:         cancel,
+		initDone:       make(chan struct{}),
+		debug:          debug,
+		LogBufferBytes: defaultLogBufferBytes,
+		ShutdownGrace:  defaultShutdownGrace,
+		trackedPIDs:    make(map[int]*Process),
 	}
 }
 
-func (m *Manager) AddProcess(name string, command string, taskType TaskType) {
+// buildCommand turns proc's command string into a fresh *exec.Cmd, applying
+// its Cwd/Env/User settings. It is called once per AddProcess and again on
+// every restart, since a *exec.Cmd cannot be reused after Wait has run.
+func (m *Manager) buildCommand(proc *Process) *exec.Cmd {
 	var cmd *exec.Cmd
 	if _, err := exec.LookPath("sh"); err == nil {
-		cmd = exec.Command("sh", "-c", command)
+		cmd = exec.Command("sh", "-c", proc.Command)
 	} else {
 		if m.debug {
-			log.Printf("Using regular command execution for %s", name)
+			log.Printf("Using regular command execution for %s", proc.Name)
 		}
-		parts := strings.Fields(command)
+		parts := strings.Fields(proc.Command)
 		if len(parts) == 0 {
-			log.Fatalf("No command provided for process %s", name)
+			log.Fatalf("No command provided for process %s", proc.Name)
 		}
 		cmd = exec.Command(parts[0], parts[1:]...)
 	}
-	m.processes = append(m.processes, &Process{
-		Name:    name,
-		Cmd:     cmd,
-		Command: command,
-		Type:    taskType,
-	})
+
+	if proc.Cwd != "" {
+		cmd.Dir = proc.Cwd
+	}
+	if len(proc.Env) > 0 {
+		cmd.Env = append(os.Environ(), proc.Env...)
+	}
+	// Each child gets its own process group so Shutdown can signal it (and
+	// anything it has spawned) as a unit via a negative pgid.
+	applyProcessGroup(cmd)
+	if proc.User != "" {
+		if err := applyProcessUser(cmd, proc.User); err != nil {
+			log.Printf("[%s] Failed to run as user %q: %v", proc.Name, proc.User, err)
+		}
+	}
+	return cmd
+}
+
+// AddProcess registers a process to be managed and returns it so callers can
+// fill in restart policy, dependencies or other per-process configuration
+// before Start.
+func (m *Manager) AddProcess(name string, command string, taskType TaskType) *Process {
+	proc := &Process{
+		Name:           name,
+		Command:        command,
+		Type:           taskType,
+		BackoffInitial: defaultBackoffInitial,
+		BackoffMax:     defaultBackoffMax,
+		Logs:           NewProcessLogBuffer(m.LogBufferBytes),
+		readyCh:        make(chan struct{}),
+	}
+	proc.Cmd = m.buildCommand(proc)
+	m.processesMu.Lock()
+	m.processes = append(m.processes, proc)
+	m.processesMu.Unlock()
+	return proc
 }
 
+// snapshotProcesses returns a copy of the managed process list, safe to
+// range over even while AddProcess (e.g. via a control-socket "reload") runs
+// concurrently on another goroutine.
+func (m *Manager) snapshotProcesses() []*Process {
+	m.processesMu.RLock()
+	defer m.processesMu.RUnlock()
+	out := make([]*Process, len(m.processes))
+	copy(out, m.processes)
+	return out
+}
+
+// Start validates the dependency graph built from every process's
+// DependsOn, then runs init tasks (in topological order, concurrently where
+// dependencies allow) before launching service tasks. A service or init
+// task only actually starts once every process it depends on is "ready":
+// an init dependency must have reported Success, a service dependency must
+// be Alive and have passed its initial healthcheck.
 func (m *Manager) Start() error {
+	deps, err := m.buildDependencyIndex()
+	if err != nil {
+		return err
+	}
+	if cycle := detectCycle(deps); cycle != nil {
+		return fmt.Errorf("dependency cycle detected: %s", strings.Join(cycle, " -> "))
+	}
+
 	initTasks := []*Process{}
 	serviceTasks := []*Process{}
-	for _, proc := range m.processes {
+	for _, proc := range m.snapshotProcesses() {
 		if proc.Type == TaskTypeInit {
 			initTasks = append(initTasks, proc)
 		} else {
 			serviceTasks = append(serviceTasks, proc)
 		}
 	}
-	if len(initTasks) > 0 {
-		if m.debug {
-			log.Println("Starting initialization tasks...")
+
+	ordered, err := topoSortInit(initTasks, deps)
+	if err != nil {
+		return err
+	}
+
+	if len(ordered) > 0 {
+		log.Println("Starting initialization tasks...")
+		var initWg sync.WaitGroup
+		for _, proc := range ordered {
+			initWg.Add(1)
+			go func(proc *Process) {
+				defer initWg.Done()
+				m.runInitTaskDependencyAware(proc)
+			}(proc)
 		}
-		for i, proc := range initTasks {
-			if m.debug {
-				log.Printf("Running init task %d/%d: %s", i+1, len(initTasks), proc.Name)
-			}
-			m.runInitTask(proc)
+		initWg.Wait()
+
+		allInitSuccess := true
+		for _, proc := range initTasks {
 			proc.mu.Lock()
 			if !proc.Success {
-				if m.debug {
-					log.Printf("Init task %s failed with exit code %d", proc.Name, proc.ExitCode)
-				}
-				close(m.initDone)
-				return fmt.Errorf("initialization task %s failed", proc.Name)
+				allInitSuccess = false
+				log.Printf("[%s] Init task failed with exit code %d", proc.Name, proc.ExitCode)
 			}
 			proc.mu.Unlock()
 		}
-		if m.debug {
-			log.Println("All initialization tasks completed successfully")
+		if !allInitSuccess {
+			log.Println("Some initialization tasks failed, not starting services")
+			m.emit(Event{Timestamp: time.Now(), Type: EventInitDone, Message: "failed"})
+			close(m.initDone)
+			return fmt.Errorf("initialization tasks failed")
 		}
+		log.Println("All initialization tasks completed successfully")
+		m.emit(Event{Timestamp: time.Now(), Type: EventInitDone, Message: "success"})
 	}
 	close(m.initDone)
+
 	if len(serviceTasks) > 0 {
-		if m.debug {
-			log.Println("Starting service tasks...")
-		}
+		log.Println("Starting service tasks...")
 		for _, proc := range serviceTasks {
+			if proc.Healthcheck != nil && proc.Healthcheck.configured() {
+				go m.runHealthcheckMonitor(proc)
+			}
 			m.wg.Add(1)
-			go m.runServiceTask(proc)
+			go func(proc *Process) {
+				if !m.waitForDeps(proc) {
+					log.Printf("[%s] Dependencies not satisfied, not starting service", proc.Name)
+					proc.mu.Lock()
+					proc.State = StateFatal
+					proc.mu.Unlock()
+					m.markReady(proc)
+					m.wg.Done()
+					return
+				}
+				m.runServiceTask(proc)
+			}(proc)
+		}
+	}
+	return nil
+}
+
+// buildDependencyIndex validates that every process name is unique and that
+// every depends_on entry refers to a known process, returning the adjacency
+// list (process name -> names it depends on).
+func (m *Manager) buildDependencyIndex() (map[string][]string, error) {
+	byName := map[string]*Process{}
+	for _, proc := range m.snapshotProcesses() {
+		if _, dup := byName[proc.Name]; dup {
+			return nil, fmt.Errorf("duplicate process name %q", proc.Name)
+		}
+		byName[proc.Name] = proc
+	}
+
+	deps := map[string][]string{}
+	for _, proc := range m.snapshotProcesses() {
+		for _, dep := range proc.DependsOn {
+			if _, ok := byName[dep]; !ok {
+				return nil, fmt.Errorf("%s depends_on unknown process %q", proc.Name, dep)
+			}
+			deps[proc.Name] = append(deps[proc.Name], dep)
+		}
+	}
+	return deps, nil
+}
+
+// detectCycle runs a DFS over the dependency graph (name -> dependency
+// names) and returns the chain of names making up the first cycle found, or
+// nil if the graph is acyclic.
+func detectCycle(deps map[string][]string) []string {
+	const (
+		white = iota
+		gray
+		black
+	)
+	color := map[string]int{}
+	var path []string
+	var cycle []string
+
+	var visit func(string) bool
+	visit = func(n string) bool {
+		color[n] = gray
+		path = append(path, n)
+		for _, d := range deps[n] {
+			switch color[d] {
+			case gray:
+				idx := 0
+				for i, p := range path {
+					if p == d {
+						idx = i
+						break
+					}
+				}
+				cycle = append(append([]string(nil), path[idx:]...), d)
+				return true
+			case white:
+				if visit(d) {
+					return true
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		color[n] = black
+		return false
+	}
+
+	names := make([]string, 0, len(deps))
+	for n := range deps {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		if color[n] == white && visit(n) {
+			return cycle
 		}
 	}
 	return nil
 }
 
+// topoSortInit orders init tasks so dependencies precede their dependents,
+// considering only edges between two init tasks (an init task waiting on a
+// service makes no sense for sequencing init output and is left to
+// waitForDeps at run time). Returns an error if the init subgraph itself
+// contains a cycle.
+func topoSortInit(initTasks []*Process, deps map[string][]string) ([]*Process, error) {
+	byName := map[string]*Process{}
+	for _, p := range initTasks {
+		byName[p.Name] = p
+	}
+
+	indegree := map[string]int{}
+	adj := map[string][]string{}
+	for name := range byName {
+		indegree[name] = 0
+	}
+	for name, ds := range deps {
+		if _, ok := byName[name]; !ok {
+			continue
+		}
+		for _, d := range ds {
+			if _, ok := byName[d]; !ok {
+				continue
+			}
+			adj[d] = append(adj[d], name)
+			indegree[name]++
+		}
+	}
+
+	queue := []string{}
+	for _, p := range initTasks {
+		if indegree[p.Name] == 0 {
+			queue = append(queue, p.Name)
+		}
+	}
+	sort.Strings(queue)
+
+	ordered := make([]*Process, 0, len(initTasks))
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		ordered = append(ordered, byName[n])
+
+		next := append([]string(nil), adj[n]...)
+		sort.Strings(next)
+		for _, d := range next {
+			indegree[d]--
+			if indegree[d] == 0 {
+				queue = append(queue, d)
+			}
+		}
+	}
+	if len(ordered) != len(initTasks) {
+		return nil, fmt.Errorf("dependency cycle detected among init tasks")
+	}
+	return ordered, nil
+}
+
+// markReady signals to any waiting dependent that proc has settled: an init
+// task has finished (successfully or not), or a service is Alive-and-healthy,
+// Fatal or Stopped. waitForDeps tells success from failure. Safe to call more
+// than once.
+func (m *Manager) markReady(proc *Process) {
+	proc.readyOnce.Do(func() { close(proc.readyCh) })
+}
+
+// waitForDeps blocks until every process proc.DependsOn has become ready,
+// returning false if the manager is shutting down or if a dependency ended
+// up in a failed/fatal state.
+func (m *Manager) waitForDeps(proc *Process) bool {
+	for _, depName := range proc.DependsOn {
+		dep := m.findProcess(depName)
+		if dep == nil {
+			return false
+		}
+		select {
+		case <-dep.readyCh:
+		case <-m.ctx.Done():
+			return false
+		}
+
+		dep.mu.Lock()
+		failed := (dep.Type == TaskTypeInit && !dep.Success) || (dep.Type == TaskTypeService && dep.State == StateFatal)
+		dep.mu.Unlock()
+		if failed {
+			return false
+		}
+	}
+	return true
+}
+
+// runInitTaskDependencyAware waits for proc's dependencies before running it
+// as an init task, then marks it ready. Start() runs its initial init-task
+// batch through it, and ReloadConfig reuses it so a reload-added init task
+// gets the same dependency gating.
+func (m *Manager) runInitTaskDependencyAware(proc *Process) {
+	if !m.waitForDeps(proc) {
+		log.Printf("[%s] Dependencies not satisfied, skipping init task", proc.Name)
+		proc.mu.Lock()
+		proc.Success = false
+		proc.ExitCode = -1
+		proc.mu.Unlock()
+		m.markReady(proc)
+		return
+	}
+	if m.debug {
+		log.Printf("Running init task: %s", proc.Name)
+	}
+	m.runInitTask(proc)
+	m.markReady(proc)
+}
+
+// verifyInitialHealth runs proc's startup healthcheck (if configured) until
+// it passes, retrying on Healthcheck.Interval, then marks proc ready so
+// dependents can start. With no healthcheck configured, proc is considered
+// ready as soon as it is Alive.
+func (m *Manager) verifyInitialHealth(proc *Process) {
+	hc := proc.Healthcheck
+	if hc == nil || !hc.configured() {
+		m.markReady(proc)
+		return
+	}
+
+	interval := hc.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	for {
+		proc.mu.Lock()
+		if !proc.Alive {
+			// Left the Running state; a later attempt re-triggers this check.
+			proc.readyCheckStarted = false
+			proc.mu.Unlock()
+			return
+		}
+		proc.mu.Unlock()
+		if runHealthcheck(proc.Name, hc) {
+			m.markReady(proc)
+			return
+		}
+		select {
+		case <-time.After(interval):
+		case <-m.ctx.Done():
+			return
+		}
+	}
+}
+
 func (m *Manager) runInitTask(proc *Process) {
-	stdout := createPrefixedWriter(proc.Name, os.Stdout)
-	stderr := createPrefixedWriter(proc.Name, os.Stderr)
-	proc.Cmd.Stdout = stdout
-	proc.Cmd.Stderr = stderr
+	// Build the command fresh, after AddProcess and all config fields
+	// (Cwd/Env/User) have been applied, the same way runServiceTask does.
+	cmd := m.buildCommand(proc)
+	proc.mu.Lock()
+	proc.Cmd = cmd
+	proc.mu.Unlock()
+	cmd.Stdout = io.MultiWriter(createPrefixedWriter(proc.Name, os.Stdout), proc.Logs)
+	cmd.Stderr = io.MultiWriter(createPrefixedWriter(proc.Name, os.Stderr), proc.Logs)
 	if m.debug {
 		log.Printf("Starting init task: %s", proc.Name)
 	}
-	if err := proc.Cmd.Start(); err != nil {
+	if err := cmd.Start(); err != nil {
 		if m.debug {
 			log.Printf("Failed to start init task %s: %v", proc.Name, err)
 		}
@@ -127,10 +479,18 @@ func (m *Manager) runInitTask(proc *Process) {
 		return
 	}
 	proc.Alive = true
+	pid := cmd.Process.Pid
+	proc.mu.Lock()
+	proc.Pgid = pid
+	proc.mu.Unlock()
+	m.trackPID(pid, proc)
+	defer m.untrackPID(pid)
+	processUp.WithLabelValues(proc.Name, proc.Type.String()).Set(1)
+	m.emit(Event{Timestamp: time.Now(), Process: proc.Name, Type: EventStarted})
 	if m.debug {
 		log.Printf("Init task %s started successfully", proc.Name)
 	}
-	if err := proc.Cmd.Wait(); err != nil {
+	if err := cmd.Wait(); err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			proc.mu.Lock()
 			proc.ExitCode = exitErr.ExitCode()
@@ -158,38 +518,301 @@ func (m *Manager) runInitTask(proc *Process) {
 		}
 	}
 	proc.Alive = false
+	proc.mu.Lock()
+	proc.Pgid = 0
+	exitCode := proc.ExitCode
+	success := proc.Success
+	proc.mu.Unlock()
+	processUp.WithLabelValues(proc.Name, proc.Type.String()).Set(0)
+	processExitCode.WithLabelValues(proc.Name).Set(float64(exitCode))
+	initTaskSuccess.WithLabelValues(proc.Name).Set(boolToFloat(success))
+	m.emit(Event{Timestamp: time.Now(), Process: proc.Name, Type: EventExited, ExitCode: exitCode})
 }
 
+// runServiceTask runs a long-running service and, depending on proc.Policy,
+// keeps restarting it with exponential backoff until it either settles into
+// a long-lived run or is marked Fatal. It exits for good once StopProcess
+// has been called and the current run exits, closing proc.done so
+// RestartProcess can wait for a clean handoff before relaunching.
 func (m *Manager) runServiceTask(proc *Process) {
 	defer m.wg.Done()
-	stdout := createPrefixedWriter(proc.Name, os.Stdout)
-	stderr := createPrefixedWriter(proc.Name, os.Stderr)
-	proc.Cmd.Stdout = stdout
-	proc.Cmd.Stderr = stderr
-	if m.debug {
-		log.Printf("Starting service: %s", proc.Name)
+
+	proc.mu.Lock()
+	proc.RetriesLeft = proc.MaxRetries
+	proc.State = StateStarting
+	proc.StopRequested = false
+	proc.Supervised = true
+	proc.done = make(chan struct{})
+	done := proc.done
+	proc.stopCh = make(chan struct{})
+	proc.stopOnce = sync.Once{}
+	proc.mu.Unlock()
+	defer func() {
+		proc.mu.Lock()
+		proc.Supervised = false
+		proc.mu.Unlock()
+		close(done)
+	}()
+
+	backoff := proc.BackoffInitial
+	if backoff <= 0 {
+		backoff = defaultBackoffInitial
 	}
-	if err := proc.Cmd.Start(); err != nil {
+
+	for {
+		// A *exec.Cmd cannot be reused after Wait, so build a fresh one on
+		// every attempt, including the first.
+		cmd := m.buildCommand(proc)
+		proc.mu.Lock()
+		proc.Cmd = cmd
+		proc.mu.Unlock()
+		cmd.Stdout = io.MultiWriter(createPrefixedWriter(proc.Name, os.Stdout), proc.Logs)
+		cmd.Stderr = io.MultiWriter(createPrefixedWriter(proc.Name, os.Stderr), proc.Logs)
+
+		if m.debug {
+			log.Printf("Starting service: %s", proc.Name)
+		}
+		start := time.Now()
+		if err := cmd.Start(); err != nil {
+			log.Printf("[%s] Failed to start service: %v", proc.Name, err)
+			proc.Alive = false
+			if !m.restartOrFatal(proc, &backoff) {
+				m.finishStoppedIfRequested(proc)
+				return
+			}
+			continue
+		}
+
+		proc.Alive = true
+		pid := cmd.Process.Pid
+		proc.mu.Lock()
+		proc.Pgid = pid
+		proc.State = StateRunning
+		firstRun := !proc.readyCheckStarted
+		proc.readyCheckStarted = true
+		proc.mu.Unlock()
+		m.trackPID(pid, proc)
+		if firstRun {
+			go m.verifyInitialHealth(proc)
+		}
+		processUp.WithLabelValues(proc.Name, proc.Type.String()).Set(1)
+		m.emit(Event{Timestamp: time.Now(), Process: proc.Name, Type: EventStarted})
 		if m.debug {
-			log.Printf("Failed to start service %s: %v", proc.Name, err)
+			log.Printf("Service %s started successfully", proc.Name)
 		}
+
+		waitErr := cmd.Wait()
+		m.untrackPID(pid)
 		proc.Alive = false
-		return
+		proc.mu.Lock()
+		proc.Pgid = 0
+		proc.mu.Unlock()
+		uptime := time.Since(start)
+		exitCode := 0
+		if waitErr != nil {
+			log.Printf("[%s] Service exited after %s with error: %v", proc.Name, uptime, waitErr)
+			if exitErr, ok := waitErr.(*exec.ExitError); ok {
+				exitCode = exitErr.ExitCode()
+			} else {
+				exitCode = -1
+			}
+		} else {
+			log.Printf("[%s] Service exited successfully after %s", proc.Name, uptime)
+		}
+		processUp.WithLabelValues(proc.Name, proc.Type.String()).Set(0)
+		processExitCode.WithLabelValues(proc.Name).Set(float64(exitCode))
+		m.emit(Event{Timestamp: time.Now(), Process: proc.Name, Type: EventExited, ExitCode: exitCode})
+
+		proc.mu.Lock()
+		stopped := proc.StopRequested
+		proc.mu.Unlock()
+		if stopped {
+			proc.mu.Lock()
+			proc.State = StateStopped
+			proc.mu.Unlock()
+			m.markReady(proc) // unblock dependents rather than hang them forever
+			return
+		}
+
+		if proc.Policy == RestartNever || (proc.Policy == RestartOnFailure && waitErr == nil) {
+			proc.mu.Lock()
+			proc.State = StateFatal
+			proc.mu.Unlock()
+			m.markReady(proc) // unblock dependents rather than hang them forever
+			return
+		}
+
+		if uptime >= time.Duration(proc.StartSeconds)*time.Second {
+			proc.mu.Lock()
+			proc.RetriesLeft = proc.MaxRetries
+			proc.mu.Unlock()
+			backoff = proc.BackoffInitial
+			if backoff <= 0 {
+				backoff = defaultBackoffInitial
+			}
+		}
+
+		if !m.restartOrFatal(proc, &backoff) {
+			m.finishStoppedIfRequested(proc)
+			return
+		}
 	}
-	proc.Alive = true
-	if m.debug {
-		log.Printf("Service %s started successfully", proc.Name)
+}
+
+// finishStoppedIfRequested marks proc StateStopped and unblocks its
+// dependents when restartOrFatal returned false because StopProcess/
+// RestartProcess interrupted a backoff sleep via stopCh, rather than because
+// retries were exhausted (which restartOrFatal already marks Fatal itself)
+// or the manager is shutting down (state doesn't matter at that point).
+func (m *Manager) finishStoppedIfRequested(proc *Process) {
+	proc.mu.Lock()
+	stopped := proc.StopRequested
+	if stopped {
+		proc.State = StateStopped
 	}
-	if err := proc.Cmd.Wait(); err != nil {
-		if m.debug {
-			log.Printf("Service %s exited with error: %v", proc.Name, err)
+	proc.mu.Unlock()
+	if stopped {
+		m.markReady(proc) // unblock dependents rather than hang them forever
+	}
+}
+
+// restartOrFatal consumes one restart attempt from proc.RetriesLeft and
+// sleeps for the current backoff before returning true to let the caller
+// retry. It returns false, having marked proc Fatal, once retries are
+// exhausted. The sleep aborts early if Manager.ctx is cancelled or
+// StopProcess/RestartProcess closes proc.stopCh.
+func (m *Manager) restartOrFatal(proc *Process, backoff *time.Duration) bool {
+	proc.mu.Lock()
+	if proc.RetriesLeft <= 0 {
+		proc.State = StateFatal
+		restarts := proc.RestartCount
+		proc.mu.Unlock()
+		m.markReady(proc) // unblock dependents rather than hang them forever
+		log.Printf("[%s] Giving up after %d restarts", proc.Name, restarts)
+		return false
+	}
+	proc.RetriesLeft--
+	proc.RestartCount++
+	proc.State = StateBackoff
+	wait := *backoff
+	max := proc.BackoffMax
+	if max <= 0 {
+		max = defaultBackoffMax
+	}
+	stopCh := proc.stopCh
+	proc.mu.Unlock()
+
+	processRestartsTotal.WithLabelValues(proc.Name).Inc()
+	m.emit(Event{Timestamp: time.Now(), Process: proc.Name, Type: EventRestarted, Message: fmt.Sprintf("restarting in %s", wait)})
+	log.Printf("[%s] Restarting in %s (retries left: %d)", proc.Name, wait, proc.RetriesLeft)
+	select {
+	case <-time.After(wait):
+	case <-m.ctx.Done():
+		return false
+	case <-stopCh:
+		return false
+	}
+
+	*backoff *= 2
+	if *backoff > max {
+		*backoff = max
+	}
+	return true
+}
+
+// StopProcess SIGTERMs name's process group (if a child is currently
+// running) or interrupts its backoff sleep (if it's between restart
+// attempts), and marks it StateStopped once its runServiceTask goroutine
+// exits, so the restart policy does not bring it back. Safe to call while
+// the supervisor is running.
+func (m *Manager) StopProcess(name string) error {
+	proc := m.findProcess(name)
+	if proc == nil {
+		return fmt.Errorf("no such process %q", name)
+	}
+
+	proc.mu.Lock()
+	alive := proc.Alive
+	supervised := proc.Supervised
+	pgid := proc.Pgid
+	stopCh := proc.stopCh
+	proc.StopRequested = true
+	proc.mu.Unlock()
+	if !alive && !supervised {
+		return fmt.Errorf("process %q is not running", name)
+	}
+	if stopCh != nil {
+		proc.stopOnce.Do(func() { close(stopCh) })
+	}
+	if pgid != 0 {
+		if err := syscall.Kill(-pgid, syscall.SIGTERM); err != nil && err != syscall.ESRCH {
+			return fmt.Errorf("signal process group %d: %w", pgid, err)
 		}
-	} else {
-		if m.debug {
-			log.Printf("Service %s exited successfully", proc.Name)
+	}
+	return nil
+}
+
+// StartProcess (re)launches name, which must be a TaskTypeService currently
+// not running. Safe to call while the supervisor is running.
+func (m *Manager) StartProcess(name string) error {
+	proc := m.findProcess(name)
+	if proc == nil {
+		return fmt.Errorf("no such process %q", name)
+	}
+	if proc.Type != TaskTypeService {
+		return fmt.Errorf("%q is not a service", name)
+	}
+
+	proc.mu.Lock()
+	supervised := proc.Supervised
+	proc.mu.Unlock()
+	if supervised {
+		return fmt.Errorf("process %q is already running or awaiting restart", name)
+	}
+
+	m.wg.Add(1)
+	go func() {
+		if !m.waitForDeps(proc) {
+			log.Printf("[%s] Dependencies not satisfied, not starting service", proc.Name)
+			proc.mu.Lock()
+			proc.State = StateFatal
+			proc.mu.Unlock()
+			m.wg.Done()
+			return
 		}
+		m.runServiceTask(proc)
+	}()
+	return nil
+}
+
+// RestartProcess stops name (if running or sleeping in backoff) and waits
+// for its current runServiceTask goroutine to fully exit before relaunching
+// it, so the old and new *exec.Cmd never overlap.
+func (m *Manager) RestartProcess(name string) error {
+	proc := m.findProcess(name)
+	if proc == nil {
+		return fmt.Errorf("no such process %q", name)
 	}
-	proc.Alive = false
+	if proc.Type != TaskTypeService {
+		return fmt.Errorf("%q is not a service", name)
+	}
+
+	proc.mu.Lock()
+	supervised := proc.Supervised
+	done := proc.done
+	proc.mu.Unlock()
+
+	if supervised {
+		// Whether cmd.Wait is blocking or runServiceTask is merely sleeping
+		// in restartOrFatal's backoff, StopProcess interrupts either one; wait
+		// for the goroutine to fully exit before spawning a new one so the
+		// old and new runServiceTask never overlap.
+		if err := m.StopProcess(name); err != nil {
+			return err
+		}
+		<-done
+	}
+	return m.StartProcess(name)
 }
 
 func (m *Manager) Wait() {
@@ -202,18 +825,49 @@ func (m *Manager) Wait() {
 	}
 }
 
+// Shutdown begins a graceful stop: SIGTERM to every process group, then,
+// if any are still alive after ShutdownGrace, SIGKILL. It blocks until
+// every process has actually exited.
 func (m *Manager) Shutdown() {
-	if m.debug {
-		log.Println("Shutting down all processes...")
+	log.Println("Shutting down all processes...")
+	m.cancel() // abort any pending restart backoff sleeps
+
+	m.signalAll(syscall.SIGTERM)
+
+	grace := m.ShutdownGrace
+	if grace <= 0 {
+		grace = defaultShutdownGrace
+	}
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(grace):
+		log.Printf("Shutdown grace period (%s) elapsed, sending SIGKILL", grace)
+		m.signalAll(syscall.SIGKILL)
+		<-done
 	}
-	m.cancel()
-	m.wg.Wait()
 }
 
+// Aggregate severity levels used to pick the HealthCheckHandler status code.
+// Backoff is "degraded but self-healing" (503); everything worse, including
+// a plain dead service with no restart policy, is treated as Fatal (500).
+const (
+	severityOK = iota
+	severityBackoff
+	severityFatal
+)
+
 func (m *Manager) HealthCheckHandler(w http.ResponseWriter, r *http.Request) {
 	response := "Health Check:\n"
-	allHealthy := true
-	if len(m.processes) == 0 {
+	severity := severityOK
+	processes := m.snapshotProcesses()
+	if len(processes) == 0 {
 		response += "No processes configured\n"
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte(response))
@@ -221,20 +875,42 @@ func (m *Manager) HealthCheckHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	select {
 	case <-m.initDone:
-		for _, proc := range m.processes {
+		for _, proc := range processes {
 			if proc.Type == TaskTypeService {
-				status := "Healthy"
-				if !proc.Alive {
-					status = "Unhealthy"
-					allHealthy = false
+				proc.mu.Lock()
+				state := proc.State
+				restarts := proc.RestartCount
+				pgid := proc.Pgid
+				health := proc.Health
+				hasHealthcheck := proc.Healthcheck != nil && proc.Healthcheck.configured()
+				proc.mu.Unlock()
+				healthSuffix := ""
+				if hasHealthcheck {
+					healthSuffix = fmt.Sprintf(", health=%s", health)
+					if health == HealthUnhealthy && severity < severityBackoff {
+						severity = severityBackoff
+					}
+				}
+				switch {
+				case state == StateStopped:
+					response += fmt.Sprintf("%s: Stopped (restarts=%d, ExitCode=%d%s)\n", proc.Name, restarts, proc.ExitCode, healthSuffix)
+				case state == StateFatal, !proc.Alive && state != StateBackoff:
+					response += fmt.Sprintf("%s: Fatal (restarts=%d, ExitCode=%d, pgid=%d%s)\n", proc.Name, restarts, proc.ExitCode, pgid, healthSuffix)
+					severity = severityFatal
+				case state == StateBackoff:
+					response += fmt.Sprintf("%s: Backoff (restarts=%d, pgid=%d%s)\n", proc.Name, restarts, pgid, healthSuffix)
+					if severity < severityBackoff {
+						severity = severityBackoff
+					}
+				default:
+					response += fmt.Sprintf("%s: Healthy (restarts=%d, pgid=%d%s)\n", proc.Name, restarts, pgid, healthSuffix)
 				}
-				response += fmt.Sprintf("%s: %s (ExitCode=%d)\n", proc.Name, status, proc.ExitCode)
 			} else {
 				proc.mu.Lock()
 				status := "Completed"
 				if !proc.Success {
 					status = "Failed"
-					allHealthy = false
+					severity = severityFatal
 				}
 				response += fmt.Sprintf("%s: %s (ExitCode=%d)\n", proc.Name, status, proc.ExitCode)
 				proc.mu.Unlock()
@@ -242,12 +918,124 @@ func (m *Manager) HealthCheckHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	default:
 		response += "Initialization in progress...\n"
-		allHealthy = false
+		severity = severityBackoff
 	}
-	if allHealthy {
-		w.WriteHeader(http.StatusOK)
-	} else {
+	response += fmt.Sprintf("Zombies reaped: %d\n", atomic.LoadInt64(&m.zombiesReaped))
+	switch severity {
+	case severityFatal:
 		w.WriteHeader(http.StatusInternalServerError)
+	case severityBackoff:
+		w.WriteHeader(http.StatusServiceUnavailable)
+	default:
+		w.WriteHeader(http.StatusOK)
 	}
 	_, _ = w.Write([]byte(response))
 }
+
+// findProcess looks up a managed process by its alias.
+func (m *Manager) findProcess(name string) *Process {
+	m.processesMu.RLock()
+	defer m.processesMu.RUnlock()
+	for _, proc := range m.processes {
+		if proc.Name == name {
+			return proc
+		}
+	}
+	return nil
+}
+
+// trackPID records that pid belongs to proc, so the PID-1 zombie reaper can
+// tell "one of our own children, already being waited on" apart from a
+// genuine orphan reparented to us.
+func (m *Manager) trackPID(pid int, proc *Process) {
+	m.pidsMu.Lock()
+	m.trackedPIDs[pid] = proc
+	m.pidsMu.Unlock()
+}
+
+func (m *Manager) untrackPID(pid int) {
+	m.pidsMu.Lock()
+	delete(m.trackedPIDs, pid)
+	m.pidsMu.Unlock()
+}
+
+func (m *Manager) isTrackedPID(pid int) bool {
+	m.pidsMu.Lock()
+	_, ok := m.trackedPIDs[pid]
+	m.pidsMu.Unlock()
+	return ok
+}
+
+var logStreamUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+// LogsHandler serves:
+//   - GET /logs                    union of every process's buffered output, "[name]"-prefixed
+//   - GET /logs/{name}?tail=200    last N lines of that process's buffered output
+//   - GET /logs/{name}/stream      upgrades to a WebSocket and tails new lines until disconnect
+func (m *Manager) LogsHandler(w http.ResponseWriter, r *http.Request) {
+	name := strings.Trim(strings.TrimPrefix(r.URL.Path, "/logs"), "/")
+	if name == "" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		for _, proc := range m.snapshotProcesses() {
+			body := strings.TrimRight(string(proc.Logs.Tail(0)), "\n")
+			if body == "" {
+				continue
+			}
+			for _, line := range strings.Split(body, "\n") {
+				fmt.Fprintf(w, "[%s] %s\n", proc.Name, line)
+			}
+		}
+		return
+	}
+
+	stream := false
+	if strings.HasSuffix(name, "/stream") {
+		name = strings.TrimSuffix(name, "/stream")
+		stream = true
+	}
+
+	proc := m.findProcess(name)
+	if proc == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if stream {
+		m.streamLogs(w, r, proc)
+		return
+	}
+
+	tail := 200
+	if v := r.URL.Query().Get("tail"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			tail = n
+		}
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write(proc.Logs.Tail(tail))
+}
+
+// streamLogs upgrades the connection to a WebSocket and forwards every new
+// write to proc.Logs until the client disconnects or it falls behind and
+// gets dropped by the ring buffer's subscriber list.
+func (m *Manager) streamLogs(w http.ResponseWriter, r *http.Request, proc *Process) {
+	conn, err := logStreamUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("[%s] Log stream upgrade failed: %v", proc.Name, err)
+		return
+	}
+	defer conn.Close()
+
+	ch, cancel := proc.Logs.Subscribe()
+	defer cancel()
+
+	for chunk := range ch {
+		if err := conn.WriteMessage(websocket.TextMessage, chunk); err != nil {
+			return
+		}
+	}
+}
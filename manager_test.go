@@ -0,0 +1,199 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDetectCycle(t *testing.T) {
+	acyclic := map[string][]string{
+		"web": {"db"},
+		"db":  {},
+	}
+	if cycle := detectCycle(acyclic); cycle != nil {
+		t.Errorf("expected no cycle, got %v", cycle)
+	}
+
+	cyclic := map[string][]string{
+		"a": {"b"},
+		"b": {"c"},
+		"c": {"a"},
+	}
+	cycle := detectCycle(cyclic)
+	if cycle == nil {
+		t.Fatal("expected a cycle to be detected")
+	}
+	if cycle[0] != cycle[len(cycle)-1] {
+		t.Errorf("cycle chain should start and end on the same name, got %v", cycle)
+	}
+}
+
+func TestBuildDependencyIndexUnknownDep(t *testing.T) {
+	mgr := NewManager(false)
+	proc := mgr.AddProcess("web", "true", TaskTypeService)
+	proc.DependsOn = []string{"missing"}
+
+	if _, err := mgr.buildDependencyIndex(); err == nil {
+		t.Error("expected an error for a depends_on referencing an unknown process")
+	}
+}
+
+func TestBuildDependencyIndexDuplicateName(t *testing.T) {
+	mgr := NewManager(false)
+	mgr.AddProcess("web", "true", TaskTypeService)
+	mgr.AddProcess("web", "true", TaskTypeService)
+
+	if _, err := mgr.buildDependencyIndex(); err == nil {
+		t.Error("expected an error for a duplicate process name")
+	}
+}
+
+func TestStartDetectsCycle(t *testing.T) {
+	mgr := NewManager(false)
+	a := mgr.AddProcess("a", "true", TaskTypeInit)
+	b := mgr.AddProcess("b", "true", TaskTypeInit)
+	a.DependsOn = []string{"b"}
+	b.DependsOn = []string{"a"}
+
+	if err := mgr.Start(); err == nil {
+		t.Error("expected Start to report the dependency cycle")
+	}
+}
+
+// TestRestartOrFatalExhaustsRetries drives restartOrFatal directly through a
+// full crash loop, without spawning any real child process, checking it
+// marks the process Fatal (not Stopped or Backoff) once RetriesLeft hits 0.
+func TestRestartOrFatalExhaustsRetries(t *testing.T) {
+	mgr := NewManager(false)
+	proc := mgr.AddProcess("flaky", "true", TaskTypeService)
+	proc.MaxRetries = 2
+	proc.RetriesLeft = proc.MaxRetries
+	proc.BackoffInitial = time.Millisecond
+	proc.BackoffMax = 5 * time.Millisecond
+	proc.stopCh = make(chan struct{})
+
+	backoff := proc.BackoffInitial
+	for i := 0; i < proc.MaxRetries; i++ {
+		if !mgr.restartOrFatal(proc, &backoff) {
+			t.Fatalf("restartOrFatal returned false early, on attempt %d", i)
+		}
+	}
+	if mgr.restartOrFatal(proc, &backoff) {
+		t.Fatal("expected restartOrFatal to return false once retries are exhausted")
+	}
+
+	proc.mu.Lock()
+	state := proc.State
+	proc.mu.Unlock()
+	if state != StateFatal {
+		t.Errorf("expected StateFatal after retries exhausted, got %s", state)
+	}
+}
+
+// TestRestartOrFatalStopChInterrupts checks that closing proc.stopCh wakes a
+// pending backoff sleep immediately, rather than waiting out the full delay.
+func TestRestartOrFatalStopChInterrupts(t *testing.T) {
+	mgr := NewManager(false)
+	proc := mgr.AddProcess("flaky", "true", TaskTypeService)
+	proc.MaxRetries = 1
+	proc.RetriesLeft = proc.MaxRetries
+	proc.BackoffInitial = time.Hour
+	proc.BackoffMax = time.Hour
+	proc.stopCh = make(chan struct{})
+	close(proc.stopCh)
+
+	backoff := proc.BackoffInitial
+	done := make(chan bool, 1)
+	go func() { done <- mgr.restartOrFatal(proc, &backoff) }()
+
+	select {
+	case ok := <-done:
+		if ok {
+			t.Error("expected restartOrFatal to return false when stopCh is closed")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("restartOrFatal did not return promptly after stopCh was closed")
+	}
+}
+
+// TestStartStopRestartProcess exercises the control-socket-facing start/
+// stop/restart paths end to end against a real (short-lived) child process,
+// checking that StartProcess refuses a second launch while one is already
+// supervised, and that RestartProcess relaunches a stopped service which
+// StopProcess can then stop again.
+func TestStartStopRestartProcess(t *testing.T) {
+	mgr := NewManager(false)
+	proc := mgr.AddProcess("svc", "sleep 5", TaskTypeService)
+	proc.Policy = RestartNever
+
+	if err := mgr.StartProcess("svc"); err != nil {
+		t.Fatalf("StartProcess: %v", err)
+	}
+	waitForState(t, proc, func(s ProcessState) bool { return s == StateRunning })
+
+	if err := mgr.StartProcess("svc"); err == nil {
+		t.Error("expected StartProcess to refuse a process that is already supervised")
+	}
+
+	if err := mgr.StopProcess("svc"); err != nil {
+		t.Fatalf("StopProcess: %v", err)
+	}
+	waitForState(t, proc, func(s ProcessState) bool { return s == StateStopped })
+
+	if err := mgr.RestartProcess("svc"); err != nil {
+		t.Fatalf("RestartProcess on a stopped process: %v", err)
+	}
+	waitForState(t, proc, func(s ProcessState) bool { return s == StateRunning })
+
+	if err := mgr.StopProcess("svc"); err != nil {
+		t.Fatalf("StopProcess: %v", err)
+	}
+	waitForState(t, proc, func(s ProcessState) bool { return s == StateStopped })
+}
+
+// TestRestartProcessDuringBackoff exercises the race the control socket's
+// "restart" command must not lose to: calling RestartProcess while a
+// service is asleep in restartOrFatal's backoff (Alive false, but still
+// Supervised) must interrupt that sleep and relaunch exactly once, never
+// leaving two runServiceTask goroutines racing the same *Process.
+func TestRestartProcessDuringBackoff(t *testing.T) {
+	mgr := NewManager(false)
+	proc := mgr.AddProcess("crasher", "false", TaskTypeService)
+	proc.Policy = RestartAlways
+	proc.MaxRetries = 100
+	proc.BackoffInitial = time.Hour
+	proc.BackoffMax = time.Hour
+
+	if err := mgr.StartProcess("crasher"); err != nil {
+		t.Fatalf("StartProcess: %v", err)
+	}
+	waitForState(t, proc, func(s ProcessState) bool { return s == StateBackoff })
+
+	if err := mgr.RestartProcess("crasher"); err != nil {
+		t.Fatalf("RestartProcess during backoff: %v", err)
+	}
+	waitForState(t, proc, func(s ProcessState) bool { return s == StateBackoff || s == StateRunning })
+
+	if err := mgr.StopProcess("crasher"); err != nil {
+		t.Fatalf("StopProcess: %v", err)
+	}
+	waitForState(t, proc, func(s ProcessState) bool { return s == StateStopped })
+}
+
+func waitForState(t *testing.T, proc *Process, match func(ProcessState) bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		proc.mu.Lock()
+		s := proc.State
+		proc.mu.Unlock()
+		if match(s) {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	proc.mu.Lock()
+	s := proc.State
+	proc.mu.Unlock()
+	t.Fatalf("timed out waiting for a matching state, last seen %s", s)
+}
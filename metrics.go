@@ -0,0 +1,46 @@
+// metrics.go
+// Prometheus metrics for external alerting stacks, served on GET /metrics
+// via promhttp.Handler. Metrics are registered once at package init and
+// updated by name/type label as processes start, exit, restart or fail
+// healthchecks, so they stay in sync with the events published in events.go.
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	processUp = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "god_process_up",
+		Help: "1 if the process is currently alive, 0 otherwise.",
+	}, []string{"name", "type"})
+
+	processRestartsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "god_process_restarts_total",
+		Help: "Total number of times a service process has been restarted.",
+	}, []string{"name"})
+
+	processExitCode = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "god_process_exit_code",
+		Help: "Exit code of the process's most recent run.",
+	}, []string{"name"})
+
+	initTaskSuccess = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "god_init_task_success",
+		Help: "1 if the init task completed successfully, 0 otherwise.",
+	}, []string{"name"})
+
+	healthcheckFailuresTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "god_healthcheck_failures_total",
+		Help: "Total number of failed healthcheck probes.",
+	}, []string{"name"})
+)
+
+// boolToFloat converts b to a Prometheus-friendly 0/1 gauge value.
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,150 @@
+// pid1.go
+// PID-1 container semantics: process-group based signalling, signal
+// forwarding to opted-in services, and reaping of orphaned children that
+// get reparented to us.
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+)
+
+// applyProcessGroup puts cmd in its own process group so Manager.Shutdown
+// can signal it (and anything it has spawned) as a unit via a negative pgid.
+func applyProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+// signalAll delivers sig to the process group of every currently running
+// process.
+func (m *Manager) signalAll(sig syscall.Signal) {
+	for _, proc := range m.snapshotProcesses() {
+		proc.mu.Lock()
+		pgid := proc.Pgid
+		proc.mu.Unlock()
+		if pgid == 0 {
+			continue
+		}
+		if err := syscall.Kill(-pgid, sig); err != nil && err != syscall.ESRCH {
+			log.Printf("[%s] Failed to send %s to pgid %d: %v", proc.Name, sig, pgid, err)
+		}
+	}
+}
+
+// ForwardSignal relays sig to every live process that opted in via
+// ForwardSignals (config "forward_signals: true").
+func (m *Manager) ForwardSignal(sig syscall.Signal) {
+	for _, proc := range m.snapshotProcesses() {
+		proc.mu.Lock()
+		pgid := proc.Pgid
+		forward := proc.ForwardSignals
+		proc.mu.Unlock()
+		if !forward || pgid == 0 {
+			continue
+		}
+		if err := syscall.Kill(pgid, sig); err != nil {
+			log.Printf("[%s] Failed to forward %s: %v", proc.Name, sig, err)
+		}
+	}
+}
+
+// SetupPID1 wires up forwarding of SIGHUP/SIGUSR1/SIGUSR2/SIGWINCH to
+// opted-in processes and, when actually running as PID 1, a SIGCHLD-driven
+// reaper for orphaned grandchildren reparented to us.
+func (m *Manager) SetupPID1() {
+	forwarded := make(chan os.Signal, 8)
+	signal.Notify(forwarded, syscall.SIGHUP, syscall.SIGUSR1, syscall.SIGUSR2, syscall.SIGWINCH)
+	go func() {
+		for sig := range forwarded {
+			if s, ok := sig.(syscall.Signal); ok {
+				m.ForwardSignal(s)
+			}
+		}
+	}()
+
+	if os.Getpid() != 1 {
+		return
+	}
+
+	log.Println("Running as PID 1, reaping orphaned children")
+	sigchld := make(chan os.Signal, 8)
+	signal.Notify(sigchld, syscall.SIGCHLD)
+	go func() {
+		for range sigchld {
+			m.reapZombies()
+		}
+	}()
+}
+
+// reapZombies finds every zombie that is actually our own child and reaps
+// the ones we did not launch ourselves (true orphans reparented to PID 1),
+// tallying them in zombiesReaped. Children we did launch must only ever be
+// reaped by their own Cmd.Wait goroutine, so isTrackedPID skips those.
+//
+// This can't be done with a repeated wait4(-1, WNOHANG|WNOWAIT) peek: WNOWAIT
+// leaves a zombie unreaped, and the kernel's child list order is stable, so
+// peeking again just resurfaces the same still-unreaped tracked pid forever
+// if it happens to sit ahead of a genuine orphan in that list — the orphan
+// would never be seen. Scanning /proc enumerates every zombie child up
+// front instead, so a tracked pid in the way can't hide an orphan behind it.
+func (m *Manager) reapZombies() {
+	for _, pid := range ownZombiePIDs() {
+		if m.isTrackedPID(pid) {
+			continue
+		}
+		var status syscall.WaitStatus
+		if _, err := syscall.Wait4(pid, &status, syscall.WNOHANG, nil); err != nil {
+			continue
+		}
+		atomic.AddInt64(&m.zombiesReaped, 1)
+		log.Printf("Reaped orphaned child pid %d (status %v)", pid, status)
+	}
+}
+
+// ownZombiePIDs scans /proc for processes that are both zombies (state "Z")
+// and direct children of this process, by reading each /proc/<pid>/stat.
+func ownZombiePIDs() []int {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil
+	}
+	self := os.Getpid()
+	var zombies []int
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+		if err != nil {
+			continue
+		}
+		// Format is "pid (comm) state ppid ...": comm can itself contain
+		// spaces or parens, so split after the last ')' rather than on
+		// whitespace from the start.
+		s := string(data)
+		i := strings.LastIndexByte(s, ')')
+		if i < 0 {
+			continue
+		}
+		fields := strings.Fields(s[i+1:])
+		if len(fields) < 2 || fields[0] != "Z" {
+			continue
+		}
+		if ppid, err := strconv.Atoi(fields[1]); err != nil || ppid != self {
+			continue
+		}
+		zombies = append(zombies, pid)
+	}
+	return zombies
+}
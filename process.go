@@ -1,10 +1,149 @@
 package main
 
 import (
+	"fmt"
 	"os/exec"
 	"sync"
+	"time"
 )
 
+// RestartPolicy controls whether a service task is re-launched after it exits.
+type RestartPolicy int
+
+const (
+	RestartNever     RestartPolicy = iota // exit is final, whatever the exit code
+	RestartOnFailure                      // only restart on a non-zero exit
+	RestartAlways                         // always restart
+)
+
+func (p RestartPolicy) String() string {
+	switch p {
+	case RestartOnFailure:
+		return "on-failure"
+	case RestartAlways:
+		return "always"
+	default:
+		return "never"
+	}
+}
+
+// ParseRestartPolicy maps the config/flag spelling of a restart policy
+// ("never", "on-failure", "always") to a RestartPolicy.
+func ParseRestartPolicy(s string) (RestartPolicy, error) {
+	switch s {
+	case "", "never":
+		return RestartNever, nil
+	case "on-failure":
+		return RestartOnFailure, nil
+	case "always":
+		return RestartAlways, nil
+	default:
+		return RestartNever, fmt.Errorf("unknown restart policy %q", s)
+	}
+}
+
+// HealthcheckMode selects how a HealthcheckSpec probes a service.
+type HealthcheckMode int
+
+const (
+	HealthcheckExec HealthcheckMode = iota // Run Command through "sh -c", exit 0 means healthy
+	HealthcheckHTTP                        // GET URL, any 2xx means healthy
+	HealthcheckTCP                         // Dial Address, a successful connect means healthy
+)
+
+func (m HealthcheckMode) String() string {
+	switch m {
+	case HealthcheckHTTP:
+		return "http"
+	case HealthcheckTCP:
+		return "tcp"
+	default:
+		return "exec"
+	}
+}
+
+// ParseHealthcheckMode maps the config spelling of a healthcheck mode
+// ("exec", "http", "tcp") to a HealthcheckMode.
+func ParseHealthcheckMode(s string) (HealthcheckMode, error) {
+	switch s {
+	case "", "exec":
+		return HealthcheckExec, nil
+	case "http":
+		return HealthcheckHTTP, nil
+	case "tcp":
+		return HealthcheckTCP, nil
+	default:
+		return HealthcheckExec, fmt.Errorf("unknown healthcheck mode %q", s)
+	}
+}
+
+// HealthcheckSpec describes how to probe a service to see if it is actually
+// ready to serve, beyond merely being an alive OS process. Interval/Timeout/
+// StartPeriod/Retries are modeled after Docker's HEALTHCHECK semantics:
+// failures during StartPeriod are recorded but don't count towards Retries,
+// which is the number of consecutive failures needed to call it Unhealthy.
+type HealthcheckSpec struct {
+	Mode    HealthcheckMode
+	Command string // exec mode: run through "sh -c"; exit 0 means healthy
+	URL     string // http mode: GET this URL; any 2xx means healthy
+	Address string // tcp mode: dial this host:port; a successful connect means healthy
+
+	Interval    time.Duration // Time between checks
+	Timeout     time.Duration // Max time to wait for one check to complete
+	StartPeriod time.Duration // Grace period after (re)start before failures count
+	Retries     int           // Consecutive failures before marking Unhealthy
+}
+
+// HealthState tracks an actively-monitored service's health, independent of
+// whether the OS process itself is merely Alive.
+type HealthState int
+
+const (
+	HealthStarting HealthState = iota
+	HealthHealthy
+	HealthUnhealthy
+)
+
+func (s HealthState) String() string {
+	switch s {
+	case HealthHealthy:
+		return "Healthy"
+	case HealthUnhealthy:
+		return "Unhealthy"
+	default:
+		return "Starting"
+	}
+}
+
+// ProcessState tracks where a service sits in the restart supervisor state
+// machine: Starting -> Running -> (Backoff -> Running)* -> Fatal. Stopped is
+// a separate terminal state reached only via a control-socket StopProcess,
+// not an error condition.
+type ProcessState int
+
+const (
+	StateStarting ProcessState = iota
+	StateRunning
+	StateBackoff
+	StateFatal
+	StateStopped
+)
+
+func (s ProcessState) String() string {
+	switch s {
+	case StateRunning:
+		return "Running"
+	case StateBackoff:
+		return "Backoff"
+	case StateStopped:
+		return "Stopped"
+	case StateFatal:
+		return "Fatal"
+	default:
+		return "Starting"
+	}
+}
+
 type Process struct {
 	Name     string     // Alias for the process
 	Cmd      *exec.Cmd  // Command to execute
@@ -14,4 +153,41 @@ type Process struct {
 	ExitCode int        // Exit code for init tasks
 	Success  bool       // Whether init task completed successfully
 	mu       sync.Mutex // Protect status operations
+
+	// Restart policy, only meaningful for TaskTypeService processes.
+	Policy         RestartPolicy
+	MaxRetries     int           // Restart attempts allowed before giving up
+	StartSeconds   int           // Minimum uptime to count as "successfully started"
+	BackoffInitial time.Duration // First backoff delay
+	BackoffMax     time.Duration // Backoff delay cap
+
+	State        ProcessState // Current supervisor state
+	RetriesLeft  int          // Restart attempts remaining in the current crash loop
+	RestartCount int          // Total number of restarts performed
+
+	Logs *ProcessLogBuffer // Ring buffer of recent combined stdout/stderr
+
+	// Declarative config, normally only set when loaded from a -config file.
+	Cwd         string           // Working directory, empty keeps the parent's
+	Env         []string         // Extra "KEY=VALUE" entries merged into os.Environ()
+	User        string           // OS user to run as, empty keeps the parent's
+	DependsOn   []string         // Names of processes that must be ready first
+	Healthcheck *HealthcheckSpec // Optional startup healthcheck gating DependsOn
+
+	readyCh           chan struct{} // Closed once proc is ready for dependents
+	readyOnce         sync.Once
+	readyCheckStarted bool // Whether verifyInitialHealth has been kicked off
+
+	Pgid           int  // Process group id of the running child, 0 if not running
+	ForwardSignals bool // Opt-in: forward SIGHUP/SIGUSR1/SIGUSR2/SIGWINCH to this process
+
+	Health         HealthState // Current active-healthcheck state, only meaningful with Healthcheck set
+	HealthFailures int         // Consecutive failed probes since the last success or (re)start
+
+	StopRequested bool          // Set by StopProcess; tells runServiceTask not to restart on exit
+	done          chan struct{} // Closed by runServiceTask when its current run's goroutine exits
+	stopCh        chan struct{} // Closed by StopProcess to interrupt a restartOrFatal backoff sleep
+	stopOnce      sync.Once     // Guards stopCh against a double close
+
+	Supervised bool // True for the whole lifetime of a runServiceTask goroutine, including backoff sleeps, not just while Alive
 }